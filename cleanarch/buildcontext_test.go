@@ -0,0 +1,164 @@
+package cleanarch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeModule materializes a tiny Go module under dir from a
+// path -> file contents map, for tests that need a real module on disk to
+// feed to go/packages.
+func writeModule(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for path, contents := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", full, err)
+		}
+		if err := os.WriteFile(full, []byte(contents), 0o644); err != nil {
+			t.Fatalf("write %s: %v", full, err)
+		}
+	}
+}
+
+// TestValidate_BuildContextUnion checks that a layering violation hidden
+// behind a GOOS-specific file is only caught once that GOOS is among the
+// configured BuildContexts, and that WithBuildContexts unions violations
+// found under every context instead of only the last one checked.
+func TestValidate_BuildContextUnion(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, map[string]string{
+		"go.mod": "module example.com/buildctx\n\ngo 1.21\n",
+		"infrastructure/infra.go": `package infrastructure
+
+func Foo() string { return "foo" }
+`,
+		// Only compiled on GOOS=linux, and violates the layer hierarchy by
+		// importing infrastructure from application.
+		"application/app_linux.go": `package application
+
+import "example.com/buildctx/infrastructure"
+
+func Bar() string { return infrastructure.Foo() }
+`,
+		// Compiled on every other GOOS, and layering-clean.
+		"application/app_other.go": `//go:build !linux
+
+package application
+
+func Bar() string { return "bar" }
+`,
+	})
+
+	alias := map[string]Layer{
+		"application":    LayerApplication,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	windowsOnly := NewValidator(alias).WithBuildContexts([]BuildContext{{GOOS: "windows"}})
+	ok, errs, err := windowsOnly.Validate(dir, false, nil)
+	if err != nil {
+		t.Fatalf("Validate (windows only): %v", err)
+	}
+	if !ok || len(errs) != 0 {
+		t.Fatalf("Validate (windows only) = ok=%v errs=%v, want no violations since app_linux.go isn't compiled on windows", ok, errs)
+	}
+
+	union := NewValidator(alias).WithBuildContexts([]BuildContext{{GOOS: "windows"}, {GOOS: "linux"}})
+	ok, errs, err = union.Validate(dir, false, nil)
+	if err != nil {
+		t.Fatalf("Validate (windows+linux): %v", err)
+	}
+	if ok || len(errs) != 1 {
+		t.Fatalf("Validate (windows+linux) = ok=%v errs=%v, want exactly 1 violation from the linux-only file", ok, errs)
+	}
+}
+
+// envGOFLAGS returns the value of the GOFLAGS entry in env, and whether one
+// was present at all.
+func envGOFLAGS(env []string) (value string, ok bool) {
+	for _, kv := range env {
+		if rest, found := strings.CutPrefix(kv, "GOFLAGS="); found {
+			return rest, true
+		}
+	}
+	return "", false
+}
+
+// TestBuildContext_Env_GOFLAGS checks that env(), given workspaceMode=false,
+// leaves GOFLAGS completely untouched (since there's no -mod conflict to fix
+// and forcing -mod=readonly would defeat -mod=vendor's ordinary
+// vendor-directory auto-detection), and given workspaceMode=true, forces
+// -mod=readonly - the only value workspace mode accepts - whatever -mod
+// value (or absence of one) the ambient GOFLAGS already has.
+func TestBuildContext_Env_GOFLAGS(t *testing.T) {
+	old, hadOld := os.LookupEnv("GOFLAGS")
+	defer func() {
+		if hadOld {
+			os.Setenv("GOFLAGS", old)
+		} else {
+			os.Unsetenv("GOFLAGS")
+		}
+	}()
+
+	os.Unsetenv("GOFLAGS")
+	if _, ok := envGOFLAGS(BuildContext{}.env(false)); ok {
+		t.Fatal("env(false) should leave GOFLAGS unset when the ambient env has none")
+	}
+
+	os.Setenv("GOFLAGS", "-mod=vendor")
+	value, ok := envGOFLAGS(BuildContext{}.env(false))
+	if !ok || value != "-mod=vendor" {
+		t.Fatalf("env(false) GOFLAGS = %q, ok=%v, want -mod=vendor left untouched outside workspace mode", value, ok)
+	}
+
+	os.Unsetenv("GOFLAGS")
+	value, ok = envGOFLAGS(BuildContext{}.env(true))
+	if !ok || value != "-mod=readonly" {
+		t.Fatalf("env(true) GOFLAGS = %q, ok=%v, want -mod=readonly forced when the ambient env has none", value, ok)
+	}
+
+	os.Setenv("GOFLAGS", "-mod=vendor -tags=integration")
+	value, ok = envGOFLAGS(BuildContext{}.env(true))
+	if !ok || !strings.Contains(value, "-tags=integration") || !strings.Contains(value, "-mod=readonly") || strings.Contains(value, "-mod=vendor") {
+		t.Fatalf("env(true) GOFLAGS = %q, ok=%v, want -mod=vendor replaced with -mod=readonly and -tags=integration preserved", value, ok)
+	}
+}
+
+// TestInWorkspaceMode checks that inWorkspaceMode correctly reports whether
+// a root is governed by an ancestor go.work, by delegating to the go
+// command's own auto-detection rather than re-implementing it.
+func TestInWorkspaceMode(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, map[string]string{
+		"go.work":    "go 1.21\n\nuse ./mod\n",
+		"mod/go.mod": "module example.com/workspaced\n\ngo 1.21\n",
+	})
+
+	inWorkspace, err := inWorkspaceMode(filepath.Join(dir, "mod"))
+	if err != nil {
+		t.Fatalf("inWorkspaceMode (workspace member): %v", err)
+	}
+	if !inWorkspace {
+		t.Fatal("inWorkspaceMode (workspace member) = false, want true since mod is used by the ancestor go.work")
+	}
+
+	// standalone lives under its own temp dir, with no go.work anywhere in
+	// its ancestry, unlike dir/mod above.
+	standalone := t.TempDir()
+	writeModule(t, standalone, map[string]string{
+		"go.mod": "module example.com/standalone\n\ngo 1.21\n",
+	})
+
+	inWorkspace, err = inWorkspaceMode(standalone)
+	if err != nil {
+		t.Fatalf("inWorkspaceMode (standalone): %v", err)
+	}
+	if inWorkspace {
+		t.Fatal("inWorkspaceMode (standalone) = true, want false since no go.work is in its ancestry")
+	}
+}
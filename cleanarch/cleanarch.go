@@ -3,13 +3,17 @@ package cleanarch
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
+	"os/exec"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
 )
 
 // Layer represents software layers.
@@ -35,168 +39,516 @@ const (
 	LayerInterfaces Layer = "interfaces"
 )
 
-var layersHierarchy = map[Layer]int{
-	LayerDomain:         1,
-	LayerApplication:    2,
-	LayerInterfaces:     3,
-	LayerInfrastructure: 4,
-}
+// packagesLoadMode is the set of go/packages facts Validate needs to resolve
+// each package's module and import graph without type-checking it.
+const packagesLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedImports | packages.NeedModule
 
-// NewValidator creates new Validator.
+// NewValidator creates a new Validator using the built-in Clean Architecture
+// layer hierarchy (domain -> application -> interfaces -> infrastructure)
+// and a single, repo-wide alias table. For per-directory aliases, a custom
+// layer hierarchy, or allow-list exceptions, build a Config (typically via
+// LoadConfig) and use NewValidatorWithConfig instead.
 func NewValidator(alias map[string]Layer) *Validator {
-	filesMetadata := make(map[string]LayerMetadata, 0)
+	cfg := DefaultConfig()
+	cfg.Aliases = []AliasRule{{Glob: "*", Alias: alias}}
+	return NewValidatorWithConfig(cfg)
+}
+
+// NewValidatorWithConfig creates a new Validator from an explicit Config,
+// typically loaded from a repo's .cleanarch.yaml via LoadConfig.
+func NewValidatorWithConfig(cfg *Config) *Validator {
 	return &Validator{
-		filesMetadata: filesMetadata,
-		alias:         alias,
+		filesMetadata: make(map[string]LayerMetadata, 0),
+		config:        cfg,
 	}
 }
 
 // ValidationError represents error when Clean Architecture rule is not keep.
 type ValidationError error
 
+// Rule identifies which layering rule a Violation breaks.
+type Rule string
+
+const (
+	// RuleLayerHierarchy means an import broke the same-module layer
+	// hierarchy, e.g. a domain package importing an infrastructure one.
+	RuleLayerHierarchy Rule = "LayerHierarchy"
+
+	// RuleCrossModule means an import broke the cross-module rule, e.g.
+	// importing a non-interfaces layer of another module.
+	RuleCrossModule Rule = "CrossModule"
+)
+
+// Violation is a structured Clean Architecture rule violation. It carries
+// everything a ValidationError's message is built from, plus the offending
+// import's source position, so CI tooling can consume it directly (see
+// cleanarch/output) instead of regex-scraping the formatted error string.
+// Violation implements error, so it is itself a valid ValidationError.
+type Violation struct {
+	Rule Rule
+
+	ImporterFile   string
+	ImporterLayer  Layer
+	ImporterModule string
+
+	ImportedPath   string
+	ImportedLayer  Layer
+	ImportedModule string
+
+	Position token.Position
+}
+
+// Error renders the same message Validate has always returned for this
+// kind of violation.
+func (v Violation) Error() string {
+	if v.Rule == RuleCrossModule {
+		return fmt.Sprintf(
+			"trying to import %s Layer (%s) to %s Layer (%s) between %s and %s modules, you can only import interfaces Layer to infrastructure Layer",
+			v.ImportedLayer, v.ImportedPath,
+			v.ImporterLayer, v.ImporterFile,
+			v.ImportedModule, v.ImporterModule,
+		)
+	}
+
+	return fmt.Sprintf(
+		"you cannot import %s Layer (%s) to %s Layer (%s)",
+		v.ImportedLayer, v.ImportedPath,
+		v.ImporterLayer, v.ImporterFile,
+	)
+}
+
+// Violations extracts the Violation values out of errs, the slice Validate
+// and ValidateWorkspace return, for callers that want to feed them to
+// cleanarch/output's JSON or SARIF renderers. Errors produced by a future
+// ValidationError implementation other than Violation are silently
+// dropped, since they carry none of the structured detail those renderers
+// need.
+func Violations(errs []ValidationError) []Violation {
+	violations := make([]Violation, 0, len(errs))
+
+	for _, err := range errs {
+		if v, ok := err.(Violation); ok {
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
+
+// BuildContext describes a GOOS/GOARCH/build-tag combination that Validate
+// should check independently, so files gated by //go:build or
+// "// +build" constraints are evaluated under every platform they can be
+// compiled for instead of only the host's.
+type BuildContext struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+// env returns the subprocess environment packages.Load should use to check
+// a root under this build context. workspaceMode (as determined once per
+// root by inWorkspaceMode, rather than re-checked per build context) says
+// whether the go command will enter workspace mode for that root: GOWORK
+// itself is always left untouched, since ValidateWorkspace relies on the go
+// command's normal go.work auto-detection to resolve workspace siblings
+// when it calls Validate once per module. But workspace mode accepts only
+// "-mod=readonly" ("-mod may only be set to readonly when in workspace
+// mode"), whatever GOFLAGS or a persisted `go env -w` default says
+// otherwise, so when workspaceMode is true, force -mod=readonly into
+// GOFLAGS. A root that isn't in workspace mode is left with whatever -mod
+// setting (including -mod=vendor) its own environment already has, since
+// there's no conflict to fix there and forcing readonly would defeat
+// vendor-directory auto-detection for ordinary, non-workspace validation.
+func (b BuildContext) env(workspaceMode bool) []string {
+	env := append([]string{}, os.Environ()...)
+
+	if workspaceMode {
+		rewritten := make([]string, 0, len(env)+1)
+		sawGOFLAGS := false
+		for _, kv := range env {
+			if rest, ok := strings.CutPrefix(kv, "GOFLAGS="); ok {
+				kv = "GOFLAGS=" + forceReadonlyFlag(rest)
+				sawGOFLAGS = true
+			}
+			rewritten = append(rewritten, kv)
+		}
+		if !sawGOFLAGS {
+			rewritten = append(rewritten, "GOFLAGS=-mod=readonly")
+		}
+		env = rewritten
+	}
+
+	if b.GOOS != "" {
+		env = append(env, "GOOS="+b.GOOS)
+	}
+	if b.GOARCH != "" {
+		env = append(env, "GOARCH="+b.GOARCH)
+	}
+	return env
+}
+
+// inWorkspaceMode reports whether the go command would enter workspace mode
+// when run from root, by asking it directly instead of re-implementing its
+// go.work auto-detection (ancestor directories, GOWORK env var, and so on).
+func inWorkspaceMode(root string) (bool, error) {
+	cmd := exec.Command("go", "env", "GOWORK")
+	cmd.Dir = root
+
+	out, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("checking workspace mode for %s: %w", root, err)
+	}
+
+	gowork := strings.TrimSpace(string(out))
+	return gowork != "" && gowork != "off", nil
+}
+
+// forceReadonlyFlag rewrites goflags' -mod setting, if any, to
+// "-mod=readonly", the only value workspace mode accepts; if goflags has no
+// -mod setting, one is appended.
+func forceReadonlyFlag(goflags string) string {
+	fields := strings.Fields(goflags)
+	for i, f := range fields {
+		if f == "-mod" && i+1 < len(fields) {
+			fields[i+1] = "readonly"
+			return strings.Join(fields, " ")
+		}
+		if strings.HasPrefix(f, "-mod=") {
+			fields[i] = "-mod=readonly"
+			return strings.Join(fields, " ")
+		}
+	}
+	return strings.Join(append(fields, "-mod=readonly"), " ")
+}
+
 // Validator is responsible for Clean Architecture validation.
 type Validator struct {
-	filesMetadata map[string]LayerMetadata
-	alias         map[string]Layer
+	filesMetadata   map[string]LayerMetadata
+	filesMetadataMu sync.RWMutex
+	config          *Config
+	buildContexts   []BuildContext
+
+	// workspaceModules, when non-nil, is the set of module paths declared
+	// by a go.work's "use" directives, as populated by ValidateWorkspace.
+	// It lets validateImport tell a workspace-sibling module (subject to
+	// the cross-module layering rule) apart from a truly external
+	// dependency (skipped), which a nil map cannot distinguish.
+	workspaceModules map[string]bool
+}
+
+// WithBuildContexts configures the GOOS/GOARCH/tag combinations that Validate
+// evaluates independently, unioning the errors found under each. Without a
+// call to WithBuildContexts, Validate only checks the host's own build
+// context, same as before.
+func (v *Validator) WithBuildContexts(contexts []BuildContext) *Validator {
+	v.buildContexts = contexts
+	return v
 }
 
 // Validate validates provided path for Clean Architecture rules.
+//
+// root is loaded as a Go module via golang.org/x/tools/go/packages, so layer
+// and module resolution follows the real import graph instead of guessing
+// module names from filesystem directory names. If WithBuildContexts was
+// used, root is loaded once per configured build context and the layering
+// rules are evaluated independently for each, since a file can belong to
+// different layers' import graphs depending on which //go:build constraints
+// are active.
 func (v *Validator) Validate(root string, ignoreTests bool, ignoredPackages []string) (bool, []ValidationError, error) {
+	contexts := v.buildContexts
+	if len(contexts) == 0 {
+		contexts = []BuildContext{{}}
+	}
+
+	// Checked once per root rather than once per build context, since
+	// it's the same go subprocess call (and the same answer) regardless
+	// of which context is being validated.
+	workspaceMode, err := inWorkspaceMode(root)
+	if err != nil {
+		return false, nil, err
+	}
+
 	errors := []ValidationError{}
 
-	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
-		if fi.IsDir() {
-			return nil
+	for _, buildCtx := range contexts {
+		ctxErrors, err := v.validateBuildContext(root, ignoreTests, ignoredPackages, buildCtx, workspaceMode)
+		if err != nil {
+			return false, nil, err
 		}
 
-		if !strings.HasSuffix(path, ".go") {
-			return nil
-		}
+		errors = append(errors, ctxErrors...)
+	}
 
-		if ignoreTests && strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
+	return len(errors) == 0, errors, nil
+}
+
+func (v *Validator) validateBuildContext(root string, ignoreTests bool, ignoredPackages []string, buildCtx BuildContext, workspaceMode bool) ([]ValidationError, error) {
+	// packages.Package.Fset is only populated when NeedTypes is requested,
+	// which would force a full type-check we don't need just for source
+	// positions. Provide our own FileSet instead so pkg.Syntax's positions
+	// resolve without paying that cost.
+	fset := token.NewFileSet()
+
+	cfg := &packages.Config{
+		Mode:  packagesLoadMode,
+		Dir:   root,
+		Tests: !ignoreTests,
+		Env:   buildCtx.env(workspaceMode),
+		Fset:  fset,
+	}
+	if len(buildCtx.Tags) > 0 {
+		cfg.BuildFlags = []string{"-tags", strings.Join(buildCtx.Tags, ",")}
+	}
 
-		if strings.Contains(path, "/vendor/") {
-			// todo - better check and flag
-			return nil
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, err
+	}
+
+	work := []fileWork{}
+
+	for _, pkg := range pkgs {
+		for _, loadErr := range pkg.Errors {
+			return nil, fmt.Errorf("loading %s: %w", pkg.PkgPath, loadErr)
 		}
 
-		if strings.Contains(path, "/.") {
-			return nil
+		importerMeta := v.packageMetadata(pkg)
+		if importerMeta.Layer == "" || importerMeta.Module == "" {
+			Log.Printf("cannot parse metadata for package %s, meta: %+v", pkg.PkgPath, importerMeta)
+			continue
 		}
 
-		fset := token.NewFileSet()
+		for _, file := range pkg.Syntax {
+			path := fset.Position(file.Pos()).Filename
 
-		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
-		if err != nil {
-			panic(err)
+			if strings.Contains(path, "/vendor/") {
+				// todo - better check and flag
+				continue
+			}
+
+			work = append(work, fileWork{pkg: pkg, file: file, fset: fset})
 		}
+	}
 
-		Log.Print("processing: ", path)
-		importerMeta := v.fileMetadata(path)
-		Log.Printf("metadata: %+v", importerMeta)
+	return v.validateFiles(work, ignoredPackages), nil
+}
 
-		if importerMeta.Layer == "" || importerMeta.Module == "" {
-			// todo - error from meta parser?
-			Log.Printf("cannot parse metadata for file %s, meta: %+v", path, importerMeta)
-			return nil
-		}
+// fileWork is one unit of validateFiles' worker pool: a single parsed file,
+// the already-loaded package it belongs to, and the FileSet its positions
+// are relative to.
+type fileWork struct {
+	pkg  *packages.Package
+	file *ast.File
+	fset *token.FileSet
+}
+
+// validateFiles checks each file's imports, fanning the work out across
+// runtime.GOMAXPROCS(0) workers since on large monorepos this is the
+// dominant validation cost. Results are collected per file and returned
+// sorted by file path, so output stays stable for diffs and golden tests
+// regardless of worker scheduling order.
+func (v *Validator) validateFiles(work []fileWork, ignoredPackages []string) []ValidationError {
+	if len(work) == 0 {
+		return []ValidationError{}
+	}
+
+	type fileResult struct {
+		path   string
+		errors []ValidationError
+	}
 
-	ImportsLoop:
-		for _, imp := range f.Imports {
-			for _, ignoredPackage := range ignoredPackages {
-				if strings.Contains(imp.Path.Value, ignoredPackage) {
-					continue ImportsLoop
+	jobs := make(chan fileWork)
+	results := make(chan fileResult)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(work) {
+		workers = len(work)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for w := range jobs {
+				results <- fileResult{
+					path:   w.fset.Position(w.file.Pos()).Filename,
+					errors: v.validateFileImports(w.pkg, w.file, w.fset, ignoredPackages),
 				}
 			}
+		}()
+	}
 
-			validationErrors := v.validateImport(imp, importerMeta, path)
-			errors = append(errors, validationErrors...)
+	go func() {
+		for _, w := range work {
+			jobs <- w
 		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	byPath := make(map[string][]ValidationError, len(work))
+	for r := range results {
+		if len(r.errors) > 0 {
+			byPath[r.path] = r.errors
+		}
+	}
 
-		return nil
-	})
-	if err != nil {
-		return false, nil, err
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
 	}
+	sort.Strings(paths)
 
-	return len(errors) == 0, errors, nil
+	errors := []ValidationError{}
+	for _, path := range paths {
+		errors = append(errors, byPath[path]...)
+	}
+
+	return errors
+}
+
+func (v *Validator) validateFileImports(pkg *packages.Package, file *ast.File, fset *token.FileSet, ignoredPackages []string) []ValidationError {
+	path := fset.Position(file.Pos()).Filename
+	importerMeta := v.packageMetadata(pkg)
+
+	errors := []ValidationError{}
+
+ImportsLoop:
+	for _, imp := range file.Imports {
+		importPath := strings.Trim(imp.Path.Value, `"`)
+
+		for _, ignoredPackage := range ignoredPackages {
+			if strings.Contains(importPath, ignoredPackage) {
+				continue ImportsLoop
+			}
+		}
+
+		importedPkg, ok := pkg.Imports[importPath]
+		if !ok {
+			// unresolved import (e.g. missing dependency), nothing to validate
+			continue
+		}
+
+		errors = append(errors, v.validateImport(imp, pkg, importedPkg, importerMeta, path, fset)...)
+	}
+
+	return errors
 }
 
-func (v *Validator) validateImport(imp *ast.ImportSpec, importerMeta LayerMetadata, path string) []ValidationError {
+func (v *Validator) validateImport(imp *ast.ImportSpec, importerPkg, imported *packages.Package, importerMeta LayerMetadata, path string, fset *token.FileSet) []ValidationError {
 	errors := []ValidationError{}
 
-	importPath := imp.Path.Value
-	importPath = strings.TrimSuffix(importPath, `"`)
-	importPath = strings.TrimPrefix(importPath, `"`)
-	importMeta := v.fileMetadata(importPath)
+	if v.config.isAllowed(importerPkg.PkgPath, imported.PkgPath) {
+		return errors
+	}
+
+	importMeta := v.packageMetadata(imported)
+
+	Log.Printf("import: %s, importMeta: %+v", imported.PkgPath, importMeta)
 
-	Log.Printf("import: %s, importMeta: %+v", importPath, importMeta)
+	position := fset.Position(imp.Pos())
 
 	if importMeta.Module == importerMeta.Module {
-		importHierarchy := layersHierarchy[importMeta.Layer]
-		importerHierarchy := layersHierarchy[importerMeta.Layer]
+		hierarchy := v.config.hierarchy()
+		importHierarchy := hierarchy[importMeta.Layer]
+		importerHierarchy := hierarchy[importerMeta.Layer]
 		Log.Printf("import hierarchy: %d, importer hierarchy: %d", importHierarchy, importerHierarchy)
 
 		if importHierarchy > importerHierarchy {
-			err := fmt.Errorf(
-				"you cannot import %s Layer (%s) to %s Layer (%s)",
-				importMeta.Layer, importPath,
-				importerMeta.Layer, path,
-			)
-			errors = append(errors, err)
+			errors = append(errors, Violation{
+				Rule:           RuleLayerHierarchy,
+				ImporterFile:   path,
+				ImporterLayer:  importerMeta.Layer,
+				ImporterModule: importerMeta.Module,
+				ImportedPath:   imported.PkgPath,
+				ImportedLayer:  importMeta.Layer,
+				ImportedModule: importMeta.Module,
+				Position:       position,
+			})
 		}
 	} else if importMeta.Layer != "" {
-		if importMeta.Layer != LayerInterfaces || importerMeta.Layer != LayerInfrastructure {
-			err := fmt.Errorf(
-				"trying to import %s Layer (%s) to %s Layer (%s) between %s and %s modules, you can only import interfaces Layer to infrastructure Layer",
-				importMeta.Layer, importPath,
-				importerMeta.Layer, path,
-				importMeta.Module, importerMeta.Module,
-			)
-			errors = append(errors, err)
+		if v.workspaceModules != nil && !v.workspaceModules[importMeta.Module] {
+			// importMeta.Module isn't a workspace sibling, so it's an
+			// ordinary external dependency and not subject to layering.
+			return errors
+		}
+
+		if !v.config.crossModuleAllowed(importMeta.Layer, importerMeta.Layer) {
+			errors = append(errors, Violation{
+				Rule:           RuleCrossModule,
+				ImporterFile:   path,
+				ImporterLayer:  importerMeta.Layer,
+				ImporterModule: importerMeta.Module,
+				ImportedPath:   imported.PkgPath,
+				ImportedLayer:  importMeta.Layer,
+				ImportedModule: importMeta.Module,
+				Position:       position,
+			})
 		}
 	}
 	return errors
 }
 
-func (v *Validator) fileMetadata(path string) LayerMetadata {
-	if metadata, ok := v.filesMetadata[path]; ok {
+// packageMetadata resolves the module and layer of a loaded package, caching
+// the result by import path so it's computed at most once across all files
+// that reference it. Safe for concurrent use by validateFiles' workers.
+func (v *Validator) packageMetadata(pkg *packages.Package) LayerMetadata {
+	v.filesMetadataMu.RLock()
+	metadata, ok := v.filesMetadata[pkg.PkgPath]
+	v.filesMetadataMu.RUnlock()
+	if ok {
 		return metadata
 	}
 
-	v.filesMetadata[path] = ParseLayerMetadata(path, v.alias)
-	return v.filesMetadata[path]
+	alias := v.config.aliasFor(pkg.PkgPath)
+	metadata = ParseLayerMetadata(pkg.PkgPath, alias)
+	if pkg.Module != nil {
+		metadata.Module = pkg.Module.Path
+	}
+
+	v.filesMetadataMu.Lock()
+	v.filesMetadata[pkg.PkgPath] = metadata
+	v.filesMetadataMu.Unlock()
+
+	return metadata
 }
 
-// LayerMetadata contains informations about directory module and software layer.
+// LayerMetadata contains informations about module and software layer.
 type LayerMetadata struct {
 	Module string
 	Layer  Layer
 }
 
-// ParseLayerMetadata parses metadata of provided path.
-func ParseLayerMetadata(path string, alias map[string]Layer) LayerMetadata {
-	pathParts := strings.Split(path, "/")
+// ParseLayerMetadata parses the layer of the provided import path.
+//
+// The layer is derived from the import path suffix rather than from
+// filesystem layout, so "github.com/acme/foo/internal/orders/domain/order"
+// resolves to layer "domain" regardless of where module foo lives on disk.
+func ParseLayerMetadata(importPath string, alias map[string]Layer) LayerMetadata {
+	pathParts := strings.Split(importPath, "/")
 
 	metadata := LayerMetadata{}
 
 	for i := len(pathParts) - 1; i >= 0; i-- {
 		pathPart := pathParts[i]
 
-		// we assume that the path upper the Layer is module name
-		if metadata.Layer != "" {
-			metadata.Module = pathPart
-			break
-		}
-
 		for alias, layer := range alias {
 			if pathPart == alias {
 				metadata.Layer = layer
-				continue
+				break
 			}
 		}
+
+		if metadata.Layer != "" {
+			break
+		}
 	}
 
 	return metadata
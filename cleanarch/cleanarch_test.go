@@ -0,0 +1,124 @@
+package cleanarch
+
+import "testing"
+
+// TestParseLayerMetadata_SuffixMatch checks that the layer is resolved by
+// walking the import path from its last segment backwards, so a layer name
+// buried deep in a nested path (or aliased to a different directory name)
+// is still found regardless of the module's own import path prefix.
+func TestParseLayerMetadata_SuffixMatch(t *testing.T) {
+	alias := map[string]Layer{
+		"domain":         LayerDomain,
+		"model":          LayerDomain,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	tests := []struct {
+		name       string
+		importPath string
+		want       Layer
+	}{
+		{"direct match", "github.com/acme/foo/domain", LayerDomain},
+		{"nested under nonmatching parents", "github.com/acme/foo/internal/orders/domain/order", LayerDomain},
+		{"aliased directory name", "github.com/acme/foo/internal/legacy/model", LayerDomain},
+		{"last matching segment wins when several appear", "github.com/acme/domain/infrastructure", LayerInfrastructure},
+		{"no matching segment", "github.com/acme/foo/internal/orders", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLayerMetadata(tt.importPath, alias)
+			if got.Layer != tt.want {
+				t.Fatalf("ParseLayerMetadata(%q).Layer = %q, want %q", tt.importPath, got.Layer, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidate_IgnoredPackages checks that an import matching an
+// ignoredPackages entry is skipped before it's resolved against the
+// package graph at all, so a layering violation hidden behind an ignored
+// import never surfaces.
+func TestValidate_IgnoredPackages(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, map[string]string{
+		"go.mod": "module example.com/ignored\n\ngo 1.21\n",
+		"infrastructure/infra.go": `package infrastructure
+
+func Foo() string { return "foo" }
+`,
+		"application/app.go": `package application
+
+import "example.com/ignored/infrastructure"
+
+func Bar() string { return infrastructure.Foo() }
+`,
+	})
+
+	alias := map[string]Layer{
+		"application":    LayerApplication,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	v := NewValidator(alias)
+
+	ok, errs, err := v.Validate(dir, false, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if ok || len(errs) != 1 {
+		t.Fatalf("Validate (no ignore) = ok=%v errs=%v, want exactly 1 violation", ok, errs)
+	}
+
+	v = NewValidator(alias)
+	ok, errs, err = v.Validate(dir, false, []string{"ignored/infrastructure"})
+	if err != nil {
+		t.Fatalf("Validate (ignored): %v", err)
+	}
+	if !ok || len(errs) != 0 {
+		t.Fatalf("Validate (ignored) = ok=%v errs=%v, want no violations since the offending import is ignored", ok, errs)
+	}
+}
+
+// TestValidate_VendorSkipped checks that files sitting under a vendor/
+// directory never produce violations, even when they'd otherwise break the
+// layer hierarchy, since vendored code isn't the repo's own to fix.
+func TestValidate_VendorSkipped(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, map[string]string{
+		"go.mod": "module example.com/vendored\n\ngo 1.21\n",
+		"infrastructure/infra.go": `package infrastructure
+
+func Foo() string { return "foo" }
+`,
+		"application/app.go": `package application
+
+func Bar() string { return "bar" }
+`,
+		// A directory literally named vendor is excluded from "./..." by the
+		// go tool itself; this file would violate the hierarchy if it were
+		// ever loaded.
+		"vendor/example.com/vendored/application/vendored_app.go": `package application
+
+import "example.com/vendored/infrastructure"
+
+func Baz() string { return infrastructure.Foo() }
+`,
+	})
+
+	alias := map[string]Layer{
+		"application":    LayerApplication,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	v := NewValidator(alias)
+	ok, errs, err := v.Validate(dir, false, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !ok || len(errs) != 0 {
+		t.Fatalf("Validate = ok=%v errs=%v, want no violations since the offending file lives under vendor/", ok, errs)
+	}
+}
@@ -0,0 +1,176 @@
+package cleanarch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the conventional name of a repo-root project config,
+// looked up by callers (e.g. the cleanarch CLI's -config flag) when none is
+// given explicitly.
+const DefaultConfigFile = ".cleanarch.yaml"
+
+// Config is the policy a Validator enforces: which layers exist and in what
+// order, which directories alias which layer names, and which imports are
+// exempted from the layering rules. It is normally loaded from a repo-root
+// .cleanarch.yaml via LoadConfig, which lets a project declare its own layer
+// set instead of being locked to domain/application/interfaces/infrastructure.
+type Config struct {
+	// Layers lists the recognized layers from innermost to outermost. A
+	// layer's position in the list is its rank in the hierarchy: importing
+	// a layer ranked after your own, within the same module, is a
+	// violation. This replaces the previously hard-coded layersHierarchy
+	// map, so projects can add layers (e.g. a "presentation" layer between
+	// application and interfaces) without a code change.
+	Layers []string `yaml:"layers"`
+
+	// Aliases maps directory globs to alias->layer tables, so different
+	// subtrees of a repo can use different naming conventions for the
+	// same layer. Rules are evaluated in order and merged, so later rules
+	// win on conflicting aliases. A glob of "*" (or the empty string)
+	// applies everywhere.
+	Aliases []AliasRule `yaml:"aliases"`
+
+	// Allow lists import exceptions that suppress a layering error which
+	// would otherwise be reported.
+	Allow []AllowRule `yaml:"allow"`
+
+	// CrossModule overrides the default cross-module rule, which only
+	// allows importing a LayerInterfaces package into a
+	// LayerInfrastructure one. When empty, the default rule applies.
+	CrossModule []CrossModuleRule `yaml:"crossModule"`
+}
+
+// AliasRule maps directory names to layers for packages whose import path
+// matches Glob.
+type AliasRule struct {
+	Glob  string           `yaml:"glob"`
+	Alias map[string]Layer `yaml:"alias"`
+}
+
+// AllowRule suppresses the layering error that would otherwise be reported
+// for an import of To from From. Both fields are import-path globs, e.g.
+// "myapp/infrastructure/persistence/*" or "myapp/application/usecases/*".
+type AllowRule struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// CrossModuleRule allows importing a package in ImportedLayer, from a
+// different module, into a package in ImporterLayer.
+type CrossModuleRule struct {
+	ImportedLayer Layer `yaml:"importedLayer"`
+	ImporterLayer Layer `yaml:"importerLayer"`
+}
+
+// DefaultConfig returns the layer hierarchy and rules go-cleanarch has
+// always enforced, used when no .cleanarch.yaml is present.
+func DefaultConfig() *Config {
+	return &Config{
+		Layers: []string{
+			string(LayerDomain),
+			string(LayerApplication),
+			string(LayerInterfaces),
+			string(LayerInfrastructure),
+		},
+	}
+}
+
+// LoadConfig reads and parses a .cleanarch.yaml project config from path.
+// Fields left unset in the file fall back to DefaultConfig.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// hierarchy builds the layer->rank lookup described by c.Layers.
+func (c *Config) hierarchy() map[Layer]int {
+	ranks := make(map[Layer]int, len(c.Layers))
+	for i, layer := range c.Layers {
+		ranks[Layer(layer)] = i + 1
+	}
+	return ranks
+}
+
+// aliasFor merges the alias tables of every AliasRule whose Glob matches
+// importPath, in order, so later rules win on conflicting aliases.
+func (c *Config) aliasFor(importPath string) map[string]Layer {
+	merged := map[string]Layer{}
+
+	for _, rule := range c.Aliases {
+		if rule.Glob == "" || rule.Glob == "*" {
+			for alias, layer := range rule.Alias {
+				merged[alias] = layer
+			}
+			continue
+		}
+
+		matched, err := path.Match(rule.Glob, importPath)
+		if err != nil {
+			Log.Printf("invalid alias glob %q: %v", rule.Glob, err)
+			continue
+		}
+
+		if matched {
+			for alias, layer := range rule.Alias {
+				merged[alias] = layer
+			}
+		}
+	}
+
+	return merged
+}
+
+// isAllowed reports whether an Allow rule exempts the import of toPath from
+// fromPath.
+func (c *Config) isAllowed(fromPath, toPath string) bool {
+	for _, rule := range c.Allow {
+		fromMatch, err := path.Match(rule.From, fromPath)
+		if err != nil {
+			Log.Printf("invalid allow.from glob %q: %v", rule.From, err)
+			continue
+		}
+
+		toMatch, err := path.Match(rule.To, toPath)
+		if err != nil {
+			Log.Printf("invalid allow.to glob %q: %v", rule.To, err)
+			continue
+		}
+
+		if fromMatch && toMatch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// crossModuleAllowed reports whether importing a package in importedLayer,
+// from a different module, into a package in importerLayer is permitted.
+// Without CrossModule rules configured, only interfaces -> infrastructure
+// is allowed, matching go-cleanarch's original behavior.
+func (c *Config) crossModuleAllowed(importedLayer, importerLayer Layer) bool {
+	if len(c.CrossModule) == 0 {
+		return importedLayer == LayerInterfaces && importerLayer == LayerInfrastructure
+	}
+
+	for _, rule := range c.CrossModule {
+		if rule.ImportedLayer == importedLayer && rule.ImporterLayer == importerLayer {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+package cleanarch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Hierarchy(t *testing.T) {
+	cfg := &Config{Layers: []string{"domain", "application", "presentation", "interfaces", "infrastructure"}}
+
+	hierarchy := cfg.hierarchy()
+
+	if hierarchy[Layer("domain")] >= hierarchy[Layer("application")] {
+		t.Fatalf("domain should rank below application, got domain=%d application=%d", hierarchy[Layer("domain")], hierarchy[Layer("application")])
+	}
+	if hierarchy[Layer("presentation")] >= hierarchy[Layer("interfaces")] {
+		t.Fatalf("presentation should rank below interfaces, got presentation=%d interfaces=%d", hierarchy[Layer("presentation")], hierarchy[Layer("interfaces")])
+	}
+	if hierarchy[Layer("interfaces")] >= hierarchy[Layer("infrastructure")] {
+		t.Fatalf("interfaces should rank below infrastructure, got interfaces=%d infrastructure=%d", hierarchy[Layer("interfaces")], hierarchy[Layer("infrastructure")])
+	}
+}
+
+// TestConfig_AliasFor_GlobMergeOrder checks that a glob-specific AliasRule
+// overrides a repo-wide "*" rule for the same alias, while still inheriting
+// the wide rule's other aliases.
+func TestConfig_AliasFor_GlobMergeOrder(t *testing.T) {
+	cfg := &Config{
+		Aliases: []AliasRule{
+			{Glob: "*", Alias: map[string]Layer{"model": LayerDomain, "usecase": LayerApplication}},
+			{Glob: "myapp/legacy/*", Alias: map[string]Layer{"model": LayerInfrastructure}},
+		},
+	}
+
+	wide := cfg.aliasFor("myapp/orders/model")
+	if wide["model"] != LayerDomain {
+		t.Fatalf("myapp/orders/model: alias %q = %q, want %q", "model", wide["model"], LayerDomain)
+	}
+
+	legacy := cfg.aliasFor("myapp/legacy/model")
+	if legacy["model"] != LayerInfrastructure {
+		t.Fatalf("myapp/legacy/model: alias %q = %q, want %q (glob-specific rule should win)", "model", legacy["model"], LayerInfrastructure)
+	}
+	if legacy["usecase"] != LayerApplication {
+		t.Fatalf("myapp/legacy/model: alias %q = %q, want %q (inherited from the '*' rule)", "usecase", legacy["usecase"], LayerApplication)
+	}
+}
+
+func TestConfig_IsAllowed(t *testing.T) {
+	cfg := &Config{
+		Allow: []AllowRule{
+			{From: "myapp/infrastructure/persistence/*", To: "myapp/application/usecases/*"},
+		},
+	}
+
+	if !cfg.isAllowed("myapp/infrastructure/persistence/foo", "myapp/application/usecases/bar") {
+		t.Fatal("expected the configured exception to suppress this import")
+	}
+	if cfg.isAllowed("myapp/infrastructure/http/foo", "myapp/application/usecases/bar") {
+		t.Fatal("expected no exception for an importer path the rule doesn't match")
+	}
+	if cfg.isAllowed("myapp/infrastructure/persistence/foo", "myapp/domain/order") {
+		t.Fatal("expected no exception for an imported path the rule doesn't match")
+	}
+}
+
+func TestConfig_CrossModuleAllowed(t *testing.T) {
+	defaultCfg := &Config{}
+	if !defaultCfg.crossModuleAllowed(LayerInterfaces, LayerInfrastructure) {
+		t.Fatal("default rule should allow interfaces -> infrastructure")
+	}
+	if defaultCfg.crossModuleAllowed(LayerDomain, LayerInfrastructure) {
+		t.Fatal("default rule should not allow domain -> infrastructure")
+	}
+
+	customCfg := &Config{
+		CrossModule: []CrossModuleRule{
+			{ImportedLayer: LayerDomain, ImporterLayer: LayerInfrastructure},
+		},
+	}
+	if !customCfg.crossModuleAllowed(LayerDomain, LayerInfrastructure) {
+		t.Fatal("configured rule should allow domain -> infrastructure")
+	}
+	if customCfg.crossModuleAllowed(LayerInterfaces, LayerInfrastructure) {
+		t.Fatal("configuring a rule should drop the implicit default, so interfaces -> infrastructure is no longer allowed")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".cleanarch.yaml")
+
+	yaml := `
+layers:
+  - domain
+  - application
+  - presentation
+  - interfaces
+  - infrastructure
+aliases:
+  - glob: "*"
+    alias:
+      model: domain
+  - glob: "myapp/legacy/*"
+    alias:
+      model: infrastructure
+allow:
+  - from: "myapp/infrastructure/persistence/*"
+    to: "myapp/application/usecases/*"
+crossModule:
+  - importedLayer: domain
+    importerLayer: infrastructure
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if len(cfg.Layers) != 5 || cfg.Layers[2] != "presentation" {
+		t.Fatalf("Layers = %v, want a 5-layer hierarchy with presentation in the middle", cfg.Layers)
+	}
+	if len(cfg.Aliases) != 2 {
+		t.Fatalf("Aliases = %v, want 2 rules", cfg.Aliases)
+	}
+	if !cfg.isAllowed("myapp/infrastructure/persistence/foo", "myapp/application/usecases/bar") {
+		t.Fatal("expected the parsed allow rule to suppress this import")
+	}
+	if !cfg.crossModuleAllowed(LayerDomain, LayerInfrastructure) {
+		t.Fatal("expected the parsed crossModule rule to allow domain -> infrastructure")
+	}
+}
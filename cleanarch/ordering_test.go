@@ -0,0 +1,71 @@
+package cleanarch
+
+import (
+	"sort"
+	"testing"
+)
+
+// TestValidate_DeterministicOrdering seeds several files, in an order that
+// doesn't match alphabetical filename order, each importing a layer they
+// shouldn't. Because validateFiles fans file checking out across a worker
+// pool, workers can finish in any order; this checks the returned errors
+// are nonetheless sorted by file path, so output stays stable for diffs
+// and golden tests regardless of scheduling.
+func TestValidate_DeterministicOrdering(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, map[string]string{
+		"go.mod": "module example.com/ordering\n\ngo 1.21\n",
+		"infrastructure/infra.go": `package infrastructure
+
+func Foo() string { return "foo" }
+`,
+		// Filenames deliberately out of alphabetical order relative to
+		// their declaration order here.
+		"application/z_first.go": `package application
+
+import "example.com/ordering/infrastructure"
+
+func Z() string { return infrastructure.Foo() }
+`,
+		"application/a_second.go": `package application
+
+import "example.com/ordering/infrastructure"
+
+func A() string { return infrastructure.Foo() }
+`,
+		"application/m_third.go": `package application
+
+import "example.com/ordering/infrastructure"
+
+func M() string { return infrastructure.Foo() }
+`,
+	})
+
+	alias := map[string]Layer{
+		"application":    LayerApplication,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	v := NewValidator(alias)
+	ok, errs, err := v.Validate(dir, false, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if ok || len(errs) != 3 {
+		t.Fatalf("Validate = ok=%v errs=%v, want 3 violations, one per file", ok, len(errs))
+	}
+
+	paths := make([]string, len(errs))
+	for i, e := range errs {
+		v, ok := e.(Violation)
+		if !ok {
+			t.Fatalf("errs[%d] = %T, want a Violation", i, e)
+		}
+		paths[i] = v.ImporterFile
+	}
+
+	if !sort.StringsAreSorted(paths) {
+		t.Fatalf("errs are not sorted by ImporterFile: %v", paths)
+	}
+}
@@ -0,0 +1,114 @@
+// Package output renders cleanarch.Violation slices as machine-readable
+// formats for CI integration, so tooling can consume structured results
+// instead of regex-scraping formatted error strings.
+package output
+
+import (
+	"encoding/json"
+
+	"github.com/ErdemOzgen/go-cleanarch/cleanarch"
+)
+
+// JSON renders violations as an indented JSON array.
+func JSON(violations []cleanarch.Violation) ([]byte, error) {
+	return json.MarshalIndent(violations, "", "  ")
+}
+
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifToolName = "go-cleanarch"
+)
+
+// SARIF renders violations as a SARIF 2.1.0 log, the format GitHub code
+// scanning and similar tools consume natively.
+func SARIF(violations []cleanarch.Violation) ([]byte, error) {
+	results := make([]sarifResult, 0, len(violations))
+
+	for _, v := range violations {
+		results = append(results, sarifResult{
+			RuleID:  string(v.Rule),
+			Level:   "error",
+			Message: sarifMessage{Text: v.Error()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: v.Position.Filename},
+					Region: sarifRegion{
+						StartLine:   v.Position.Line,
+						StartColumn: v.Position.Column,
+					},
+				},
+			}},
+		})
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name: sarifToolName,
+				Rules: []sarifRule{
+					{ID: string(cleanarch.RuleLayerHierarchy)},
+					{ID: string(cleanarch.RuleCrossModule)},
+				},
+			}},
+			Results: results,
+		}},
+	}
+
+	return json.MarshalIndent(log, "", "  ")
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
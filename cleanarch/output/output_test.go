@@ -0,0 +1,158 @@
+package output
+
+import (
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/ErdemOzgen/go-cleanarch/cleanarch"
+)
+
+func testViolation() cleanarch.Violation {
+	return cleanarch.Violation{
+		Rule:           cleanarch.RuleLayerHierarchy,
+		ImporterFile:   "application/app.go",
+		ImporterLayer:  cleanarch.LayerApplication,
+		ImporterModule: "example.com/app",
+		ImportedPath:   "example.com/app/infrastructure",
+		ImportedLayer:  cleanarch.LayerInfrastructure,
+		ImportedModule: "example.com/app",
+		Position:       token.Position{Filename: "application/app.go", Line: 7, Column: 2},
+	}
+}
+
+// TestJSON checks that JSON renders a violation as an indented array whose
+// fields round-trip through cleanarch.Violation's own JSON tags (the
+// implicit encoding/json struct tags, since Violation declares none of its
+// own), rather than asserting a single golden byte string that would break
+// on every harmless field reorder.
+func TestJSON(t *testing.T) {
+	violations := []cleanarch.Violation{testViolation()}
+
+	data, err := JSON(violations)
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+
+	var got []cleanarch.Violation
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal JSON output: %v\ndata: %s", err, data)
+	}
+
+	if len(got) != 1 || got[0] != violations[0] {
+		t.Fatalf("JSON round-trip = %+v, want %+v", got, violations)
+	}
+
+	if data[0] != '[' {
+		t.Fatalf("JSON output = %s, want it to start with an indented array", data)
+	}
+}
+
+// TestJSON_Empty checks that an empty violation slice renders as an empty
+// JSON array, not null, so downstream tooling can always range over it.
+func TestJSON_Empty(t *testing.T) {
+	data, err := JSON([]cleanarch.Violation{})
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+
+	if string(data) != "[]" {
+		t.Fatalf("JSON([]) = %s, want []", data)
+	}
+}
+
+// TestSARIF checks the rendered log matches the SARIF 2.1.0 schema shape:
+// top-level version/$schema, a single run with the tool driver name and
+// both rule IDs, and one result per violation carrying the rule ID,
+// message, and file/line/column location SARIF consumers (e.g. GitHub code
+// scanning) expect.
+func TestSARIF(t *testing.T) {
+	v := testViolation()
+
+	data, err := SARIF([]cleanarch.Violation{v})
+	if err != nil {
+		t.Fatalf("SARIF: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("unmarshal SARIF output: %v\ndata: %s", err, data)
+	}
+
+	if log.Version != sarifVersion {
+		t.Fatalf("log.Version = %q, want %q", log.Version, sarifVersion)
+	}
+	if log.Schema != sarifSchema {
+		t.Fatalf("log.Schema = %q, want %q", log.Schema, sarifSchema)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("len(log.Runs) = %d, want 1", len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != sarifToolName {
+		t.Fatalf("run.Tool.Driver.Name = %q, want %q", run.Tool.Driver.Name, sarifToolName)
+	}
+
+	wantRules := []string{string(cleanarch.RuleLayerHierarchy), string(cleanarch.RuleCrossModule)}
+	if len(run.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("run.Tool.Driver.Rules = %v, want %v", run.Tool.Driver.Rules, wantRules)
+	}
+	for i, rule := range run.Tool.Driver.Rules {
+		if rule.ID != wantRules[i] {
+			t.Fatalf("run.Tool.Driver.Rules[%d].ID = %q, want %q", i, rule.ID, wantRules[i])
+		}
+	}
+
+	if len(run.Results) != 1 {
+		t.Fatalf("len(run.Results) = %d, want 1", len(run.Results))
+	}
+
+	result := run.Results[0]
+	if result.RuleID != string(v.Rule) {
+		t.Fatalf("result.RuleID = %q, want %q", result.RuleID, v.Rule)
+	}
+	if result.Level != "error" {
+		t.Fatalf("result.Level = %q, want %q", result.Level, "error")
+	}
+	if result.Message.Text != v.Error() {
+		t.Fatalf("result.Message.Text = %q, want %q", result.Message.Text, v.Error())
+	}
+
+	if len(result.Locations) != 1 {
+		t.Fatalf("len(result.Locations) = %d, want 1", len(result.Locations))
+	}
+
+	loc := result.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != v.Position.Filename {
+		t.Fatalf("loc.ArtifactLocation.URI = %q, want %q", loc.ArtifactLocation.URI, v.Position.Filename)
+	}
+	if loc.Region.StartLine != v.Position.Line {
+		t.Fatalf("loc.Region.StartLine = %d, want %d", loc.Region.StartLine, v.Position.Line)
+	}
+	if loc.Region.StartColumn != v.Position.Column {
+		t.Fatalf("loc.Region.StartColumn = %d, want %d", loc.Region.StartColumn, v.Position.Column)
+	}
+}
+
+// TestSARIF_Empty checks that an empty violation slice still renders a
+// well-formed SARIF log with an empty results array, not null, so SARIF
+// consumers always see a valid run even on a clean validation.
+func TestSARIF_Empty(t *testing.T) {
+	data, err := SARIF(nil)
+	if err != nil {
+		t.Fatalf("SARIF: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("unmarshal SARIF output: %v\ndata: %s", err, data)
+	}
+
+	if len(log.Runs) != 1 {
+		t.Fatalf("len(log.Runs) = %d, want 1", len(log.Runs))
+	}
+	if log.Runs[0].Results == nil || len(log.Runs[0].Results) != 0 {
+		t.Fatalf("log.Runs[0].Results = %v, want a non-nil empty slice", log.Runs[0].Results)
+	}
+}
@@ -0,0 +1,70 @@
+package cleanarch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// ValidateWorkspace validates every module declared by a go.work file at
+// root. Each module is loaded and checked independently with Validate, and
+// the cross-module layering rule is applied only between workspace-sibling
+// modules (those listed by a "use" directive); imports of modules outside
+// the workspace are treated as ordinary external dependencies and skipped,
+// which Validate alone cannot tell apart from a sibling.
+func (v *Validator) ValidateWorkspace(root string, ignoreTests bool, ignoredPackages []string) (bool, []ValidationError, error) {
+	workFile := filepath.Join(root, "go.work")
+
+	data, err := ioutil.ReadFile(workFile)
+	if err != nil {
+		return false, nil, err
+	}
+
+	work, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return false, nil, fmt.Errorf("parsing %s: %w", workFile, err)
+	}
+
+	siblings := make(map[string]bool, len(work.Use))
+	for _, use := range work.Use {
+		modDir := filepath.Join(root, use.Path)
+
+		modPath, err := modulePath(modDir)
+		if err != nil {
+			return false, nil, fmt.Errorf("resolving module at %s: %w", modDir, err)
+		}
+
+		siblings[modPath] = true
+	}
+
+	v.workspaceModules = siblings
+
+	ok := true
+	errors := []ValidationError{}
+
+	for _, use := range work.Use {
+		modDir := filepath.Join(root, use.Path)
+
+		moduleOK, moduleErrors, err := v.Validate(modDir, ignoreTests, ignoredPackages)
+		if err != nil {
+			return false, nil, err
+		}
+
+		ok = ok && moduleOK
+		errors = append(errors, moduleErrors...)
+	}
+
+	return ok, errors, nil
+}
+
+// modulePath reads the module path declared by dir's go.mod.
+func modulePath(dir string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+
+	return modfile.ModulePath(data), nil
+}
@@ -0,0 +1,132 @@
+package cleanarch
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestValidateWorkspace_SiblingVsExternal builds a two-module workspace
+// (moda, modb) plus a third module (modc) that exists on disk and is
+// importable from modb via a require+replace directive (the way any
+// ordinary path-based dependency would be), but isn't listed in go.work's
+// "use" directives. moda, by contrast, is resolved purely through go.work's
+// workspace mode, with no require or replace directive naming it in modb's
+// go.mod at all - that's the only thing that makes it a workspace sibling
+// rather than an ordinary external dependency. It checks that
+// ValidateWorkspace flags the cross-module import of the workspace sibling
+// (moda) while silently skipping the import of the non-sibling module
+// (modc), something a plain Validate has no way to tell apart.
+func TestValidateWorkspace_SiblingVsExternal(t *testing.T) {
+	root := t.TempDir()
+
+	writeModule(t, root, map[string]string{
+		"go.work": "go 1.21\n\nuse (\n\t./moda\n\t./modb\n)\n",
+
+		"moda/go.mod": "module example.com/moda\n\ngo 1.21\n",
+		"moda/domain/domain.go": `package domain
+
+func Foo() string { return "foo" }
+`,
+
+		"modc/go.mod": "module example.com/modc\n\ngo 1.21\n",
+		"modc/domain/domain.go": `package domain
+
+func Foo() string { return "foo" }
+`,
+
+		"modb/go.mod": `module example.com/modb
+
+go 1.21
+
+require example.com/modc v0.0.0
+
+replace example.com/modc => ../modc
+`,
+		"modb/infrastructure/infra.go": `package infrastructure
+
+import (
+	modadomain "example.com/moda/domain"
+	modcdomain "example.com/modc/domain"
+)
+
+func Foo() string { return modadomain.Foo() + modcdomain.Foo() }
+`,
+	})
+
+	alias := map[string]Layer{
+		"domain":         LayerDomain,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	v := NewValidator(alias)
+	ok, errs, err := v.ValidateWorkspace(root, false, nil)
+	if err != nil {
+		t.Fatalf("ValidateWorkspace: %v", err)
+	}
+	if ok || len(errs) != 1 {
+		t.Fatalf("ValidateWorkspace = ok=%v errs=%v, want exactly 1 violation from the moda (sibling) import; the modc (non-sibling) import should be skipped", ok, errs)
+	}
+
+	violation, ok := errs[0].(Violation)
+	if !ok {
+		t.Fatalf("errs[0] = %T, want a Violation", errs[0])
+	}
+	if violation.ImportedModule != "example.com/moda" {
+		t.Fatalf("violation.ImportedModule = %q, want %q (the modc import should never have produced a violation)", violation.ImportedModule, "example.com/moda")
+	}
+	if filepath.Base(violation.ImporterFile) != "infra.go" {
+		t.Fatalf("violation.ImporterFile = %q, want .../infra.go", violation.ImporterFile)
+	}
+}
+
+// TestValidateWorkspace_UseOrderIndependent is a regression test for a bug
+// where resolving workspace siblings depended on go.work listing the
+// importer module before the module it imports. It's the same layout as
+// TestValidateWorkspace_SiblingVsExternal's moda/modb pair, but with the
+// "use" directives reversed (modb, the importer, listed first) and no
+// require or replace directive for moda anywhere - the cross-module
+// violation must still be caught regardless of "use" order, since nothing
+// about real go.work resolution depends on it.
+func TestValidateWorkspace_UseOrderIndependent(t *testing.T) {
+	root := t.TempDir()
+
+	writeModule(t, root, map[string]string{
+		"go.work": "go 1.21\n\nuse (\n\t./modb\n\t./moda\n)\n",
+
+		"moda/go.mod": "module example.com/moda\n\ngo 1.21\n",
+		"moda/domain/domain.go": `package domain
+
+func Foo() string { return "foo" }
+`,
+
+		"modb/go.mod": "module example.com/modb\n\ngo 1.21\n",
+		"modb/infrastructure/infra.go": `package infrastructure
+
+import modadomain "example.com/moda/domain"
+
+func Foo() string { return modadomain.Foo() }
+`,
+	})
+
+	alias := map[string]Layer{
+		"domain":         LayerDomain,
+		"infrastructure": LayerInfrastructure,
+	}
+
+	v := NewValidator(alias)
+	ok, errs, err := v.ValidateWorkspace(root, false, nil)
+	if err != nil {
+		t.Fatalf("ValidateWorkspace: %v", err)
+	}
+	if ok || len(errs) != 1 {
+		t.Fatalf("ValidateWorkspace = ok=%v errs=%v, want exactly 1 violation from the moda import, regardless of go.work's use order", ok, errs)
+	}
+
+	violation, ok := errs[0].(Violation)
+	if !ok {
+		t.Fatalf("errs[0] = %T, want a Violation", errs[0])
+	}
+	if violation.ImportedModule != "example.com/moda" {
+		t.Fatalf("violation.ImportedModule = %q, want %q", violation.ImportedModule, "example.com/moda")
+	}
+}